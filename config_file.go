@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+// fileConfig is the yaml document read from config.file. each field maps to the flag noted next to it.
+type fileConfig struct {
+	Fqdn     string `yaml:"fqdn"`     // app.fqdn
+	Protocol string `yaml:"protocol"` // app.protocol
+	Token    string `yaml:"token"`    // app.token
+	Port     string `yaml:"port"`     // svc.port
+	Address  string `yaml:"address"`  // svc.address
+	Timeout  string `yaml:"timeout"`  // http.timeout, as a duration (ie. 10s)
+}
+
+// loadConfigFile sets the flags of fs from the yaml file at path. a flag passed on the command line keeps its value,
+// so the file only replaces defaults. unknown keys are an error.
+func loadConfigFile(fs *flag.FlagSet, path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var c fileConfig
+	err = yaml.UnmarshalStrict(b, &c)
+	if err != nil {
+		return fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+
+	values := []struct{ name, value string }{
+		{"app.fqdn", c.Fqdn},
+		{"app.protocol", c.Protocol},
+		{"app.token", c.Token},
+		{"svc.port", c.Port},
+		{"svc.address", c.Address},
+		{"http.timeout", c.Timeout},
+	}
+
+	// the deprecated svc.timeout and app.protocal on the command line still win over the file
+	passed := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		passed[f.Name] = true
+	})
+	if passed["svc.timeout"] {
+		passed["http.timeout"] = true
+	}
+	if passed["app.protocal"] {
+		passed["app.protocol"] = true
+	}
+
+	for _, v := range values {
+		if v.value == "" || passed[v.name] {
+			continue
+		}
+		err := fs.Set(v.name, v.value)
+		if err != nil {
+			return fmt.Errorf("invalid %s in %s: %w", v.name, path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newConfigFlagSet defines the flags config.file maps to, with the exporter's defaults.
+func newConfigFlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("app.fqdn", "", "")
+	fs.String("app.protocol", "", "")
+	fs.String("app.protocal", "https", "")
+	fs.String("app.token", "", "")
+	fs.String("svc.port", "9998", "")
+	fs.String("svc.address", "0.0.0.0", "")
+	fs.Duration("http.timeout", 10*time.Second, "")
+	fs.Int("svc.timeout", 10, "")
+	return fs
+}
+
+// writeConfigFile writes content to a config file in a temporary directory and returns its path.
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yml")
+	err := os.WriteFile(path, []byte(content), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		file string
+		want map[string]string
+	}{
+		{
+			name: "full",
+			file: "fqdn: jira.domain.com\nprotocol: http\ntoken: dXNlcjpwYXNz\nport: \"9999\"\naddress: 127.0.0.1\ntimeout: 5s\n",
+			want: map[string]string{
+				"app.fqdn":     "jira.domain.com",
+				"app.protocol": "http",
+				"app.token":    "dXNlcjpwYXNz",
+				"svc.port":     "9999",
+				"svc.address":  "127.0.0.1",
+				"http.timeout": "5s",
+			},
+		},
+		{
+			name: "partial with flag overrides",
+			args: []string{"-app.fqdn=confluence.domain.com", "-svc.timeout=3"},
+			file: "fqdn: jira.domain.com\ntoken: dXNlcjpwYXNz\ntimeout: 5s\n",
+			want: map[string]string{
+				"app.fqdn":     "confluence.domain.com",
+				"app.protocol": "",
+				"app.token":    "dXNlcjpwYXNz",
+				"svc.port":     "9998",
+				"http.timeout": "10s",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		fs := newConfigFlagSet()
+		err := fs.Parse(tt.args)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = loadConfigFile(fs, writeConfigFile(t, tt.file))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		for name, want := range tt.want {
+			if got := fs.Lookup(name).Value.String(); got != want {
+				t.Errorf("%s: got %s=%q, want %q", tt.name, name, got, want)
+			}
+		}
+	}
+}
+
+func TestLoadConfigFileErrors(t *testing.T) {
+	tests := map[string]string{
+		"malformed":        "fqdn: [jira.domain.com\n",
+		"unknown key":      "fqdn: jira.domain.com\nusername: admin\n",
+		"invalid duration": "timeout: ten seconds\n",
+	}
+
+	for name, file := range tests {
+		path := writeConfigFile(t, file)
+		err := loadConfigFile(newConfigFlagSet(), path)
+		if err == nil {
+			t.Errorf("%s: got no error", name)
+			continue
+		}
+		if !strings.Contains(err.Error(), path) {
+			t.Errorf("%s: the error %q doesn't name the file", name, err)
+		}
+	}
+
+	if err := loadConfigFile(newConfigFlagSet(), filepath.Join(t.TempDir(), "missing.yml")); err == nil {
+		t.Error("got no error for a missing file")
+	}
+}
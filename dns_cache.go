@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// dnsCacheHits counts the lookups answered from the dns cache instead of the resolver.
+// it is created by newDNSCache, after metrics.namespace is applied.
+var dnsCacheHits prometheus.Counter
+
+// dnsCache keeps successful host lookups for ttl so a flaky resolver doesn't fail every scrape.
+type dnsCache struct {
+	ttl      time.Duration
+	resolver *net.Resolver
+
+	mu      sync.Mutex
+	entries map[string]dnsCacheEntry
+}
+
+// dnsCacheEntry is a resolved host and when it should be looked up again.
+type dnsCacheEntry struct {
+	addrs   []string
+	expires time.Time
+}
+
+// newDNSCache is the constructor for the dns cache using the default resolver.
+func newDNSCache(ttl time.Duration) *dnsCache {
+	dnsCacheHits = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: exporterName + "_dns_cache_hits_total",
+		Help: "Number of host lookups answered from the exporter's dns cache",
+	})
+
+	return &dnsCache{
+		ttl:      ttl,
+		resolver: net.DefaultResolver,
+		entries:  make(map[string]dnsCacheEntry),
+	}
+}
+
+// lookup returns the cached addresses for host, or resolves and caches them when missing or expired.
+// failed lookups are never cached.
+func (c *dnsCache) lookup(ctx context.Context, host string) ([]string, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[host]
+	c.mu.Unlock()
+
+	if ok && time.Now().Before(entry.expires) {
+		log.Debug("dns cache hit for: ", host)
+		dnsCacheHits.Inc()
+		return entry.addrs, nil
+	}
+
+	log.Debug("dns cache miss, resolve: ", host)
+	addrs, err := c.resolver.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.entries[host] = dnsCacheEntry{addrs: addrs, expires: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+
+	return addrs, nil
+}
+
+// dialContext returns a DialContext for a http.Transport that resolves hosts through the cache.
+// each cached address is tried in order until one connects.
+func (c *dnsCache) dialContext(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		// nothing to resolve for ip literals
+		if net.ParseIP(host) != nil {
+			return dialer.DialContext(ctx, network, addr)
+		}
+
+		addrs, err := c.lookup(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(addrs) == 0 {
+			return nil, fmt.Errorf("no addresses found for host: %s", host)
+		}
+
+		var conn net.Conn
+		for _, a := range addrs {
+			conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(a, port))
+			if err == nil {
+				return conn, nil
+			}
+		}
+
+		return nil, err
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	tests := map[string]string{
+		"app.token":            "AIHE_APP_TOKEN",
+		"app.fqdn":             "AIHE_APP_FQDN",
+		"svc.port":             "AIHE_SVC_PORT",
+		"metrics.emit-healthy": "AIHE_METRICS_EMIT_HEALTHY",
+	}
+	for name, want := range tests {
+		if got := envName(name); got != want {
+			t.Errorf("envName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+// setEnv sets the environment variable until the test ends.
+func setEnv(t *testing.T, key, value string) {
+	t.Helper()
+	previous, ok := os.LookupEnv(key)
+	os.Setenv(key, value)
+	t.Cleanup(func() {
+		if ok {
+			os.Setenv(key, previous)
+			return
+		}
+		os.Unsetenv(key)
+	})
+}
+
+func TestApplyEnv(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fqdn := fs.String("app.fqdn", "", "")
+	token := fs.String("app.token", "", "")
+	port := fs.String("svc.port", "9998", "")
+	emit := fs.Bool("metrics.emit-healthy", true, "")
+	err := fs.Parse([]string{"-svc.port=9000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setEnv(t, "AIHE_APP_FQDN", "jira.domain.com")
+	setEnv(t, "AIHE_SVC_PORT", "9999")
+	setEnv(t, "AIHE_METRICS_EMIT_HEALTHY", "false")
+
+	err = applyEnv(fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *fqdn != "jira.domain.com" {
+		t.Errorf("got app.fqdn %q, want it from AIHE_APP_FQDN", *fqdn)
+	}
+	if *token != "" {
+		t.Errorf("got app.token %q, want the default without AIHE_APP_TOKEN", *token)
+	}
+	if *port != "9000" {
+		t.Errorf("got svc.port %q, want the command line to win over AIHE_SVC_PORT", *port)
+	}
+	if *emit {
+		t.Error("got metrics.emit-healthy true, want it from AIHE_METRICS_EMIT_HEALTHY")
+	}
+}
+
+func TestApplyEnvInvalid(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Int("svc.timeout", 10, "")
+	setEnv(t, "AIHE_SVC_TIMEOUT", "ten")
+
+	if err := applyEnv(fs); err == nil {
+		t.Error("got no error for an AIHE_SVC_TIMEOUT that isn't a number")
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"net"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// grpcHealthUpdateInterval is how often the grpc health status is recomputed from the collector's readiness.
+const grpcHealthUpdateInterval = 5 * time.Second
+
+// serveGRPCHealth serves the grpc.health.v1.Health service on addr. the overall ("") service reports SERVING
+// only once the exporter has had a successful scrape within maxStaleness, NOT_SERVING otherwise.
+func serveGRPCHealth(addr string, state exporterState, maxStaleness time.Duration) (*grpc.Server, error) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	srv := grpc.NewServer()
+	hs := health.NewServer()
+	hs.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(srv, hs)
+
+	go func() {
+		for {
+			status := healthpb.HealthCheckResponse_NOT_SERVING
+			if state.ready(maxStaleness) {
+				status = healthpb.HealthCheckResponse_SERVING
+			}
+			hs.SetServingStatus("", status)
+			time.Sleep(grpcHealthUpdateInterval)
+		}
+	}()
+
+	go func() {
+		err := srv.Serve(lis)
+		if err != nil {
+			log.Fatal("grpc health Serve Error: ", err)
+		}
+	}()
+
+	return srv, nil
+}
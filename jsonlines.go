@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// jsonLine is a single check written by the jsonlines output, with the fqdn and when it was scraped.
+type jsonLine struct {
+	instanceHealthStatus
+	Fqdn      string `json:"fqdn"`
+	Timestamp string `json:"timestamp"`
+}
+
+// jsonLinesWriter periodically scrapes the endpoint on its own, separate from prometheus, and writes one json object per check.
+type jsonLinesWriter struct {
+	w        io.Writer
+	interval time.Duration
+}
+
+// run writes the checks every interval.
+func (j *jsonLinesWriter) run() {
+	for {
+		j.write()
+		time.Sleep(j.interval)
+	}
+}
+
+// write scrapes the endpoint and writes each check left by the /metrics filters as a line of json, nothing is
+// written when the scrape fails.
+func (j *jsonLinesWriter) write() {
+	m, _, _, err := fetchInstanceHealth(context.Background(), defaultTarget)
+	if err != nil {
+		log.Warn("jsonlines scrape failed: ", err)
+		return
+	}
+
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+	enc := json.NewEncoder(j.w)
+	for _, status := range filterStatuses(m.Statuses) {
+		err := enc.Encode(jsonLine{instanceHealthStatus: status, Fqdn: *fqdn, Timestamp: timestamp})
+		if err != nil {
+			log.Warn("unable to write the jsonlines output: ", err)
+			return
+		}
+	}
+}
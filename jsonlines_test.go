@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestJSONLinesWrite(t *testing.T) {
+	defer func(target scrapeTarget, f string) { defaultTarget, *fqdn = target, f }(defaultTarget, *fqdn)
+	setFilters(t)
+
+	u := newTestUpstream(t, respond(http.StatusOK, filteredPayload))
+	defaultTarget = u.target()
+	*fqdn = u.host()
+
+	var buf bytes.Buffer
+	(&jsonLinesWriter{w: &buf}).write()
+
+	var keys []string
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var line jsonLine
+		err := json.Unmarshal(scanner.Bytes(), &line)
+		if err != nil {
+			t.Fatalf("the line %q doesn't parse: %v", scanner.Text(), err)
+		}
+		if line.Fqdn != u.host() || line.Timestamp == "" {
+			t.Errorf("got fqdn %q and timestamp %q, want %q and the scrape time", line.Fqdn, line.Timestamp, u.host())
+		}
+		keys = append(keys, line.CompleteKey)
+	}
+
+	want := []string{"com.atlassian.jira:eol", "com.atlassian.jira:db"}
+	if len(keys) != len(want) || keys[0] != want[0] || keys[1] != want[1] {
+		t.Errorf("got the checks %v, want %v", keys, want)
+	}
+}
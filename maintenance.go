@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maintenanceWindow is a daily window of the exporter's local clock, in minutes since midnight.
+// a window whose end is before its start crosses midnight.
+type maintenanceWindow struct {
+	start int
+	end   int
+}
+
+// parseMaintenanceSchedule parses comma separated HH:MM-HH:MM windows (ie. "02:00-04:00,23:30-00:30").
+func parseMaintenanceSchedule(schedule string) ([]maintenanceWindow, error) {
+	var windows []maintenanceWindow
+	for _, w := range strings.Split(schedule, ",") {
+		w = strings.TrimSpace(w)
+		if w == "" {
+			continue
+		}
+
+		parts := strings.Split(w, "-")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("window %q is not HH:MM-HH:MM", w)
+		}
+		start, err := minuteOfDay(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("window %q: %w", w, err)
+		}
+		end, err := minuteOfDay(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("window %q: %w", w, err)
+		}
+		windows = append(windows, maintenanceWindow{start: start, end: end})
+	}
+	return windows, nil
+}
+
+// minuteOfDay parses HH:MM into minutes since midnight.
+func minuteOfDay(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// inMaintenance reports whether t falls in any of the windows, the start is inclusive and the end exclusive.
+func inMaintenance(windows []maintenanceWindow, t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	for _, w := range windows {
+		if w.start <= w.end {
+			if minute >= w.start && minute < w.end {
+				return true
+			}
+			continue
+		}
+		if minute >= w.start || minute < w.end {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// decodeParallel splits the statuses array of body into its raw elements and decodes them in workers
+// concurrent chunks, merged back in the original order. the first error of any chunk is returned.
+// errorMessages is decoded as is, so an atlassian error object is reported the same as with json.Unmarshal.
+func decodeParallel(body []byte, workers int) (instanceHealthEndpoint, error) {
+	var raw struct {
+		Statuses      []json.RawMessage `json:"statuses"`
+		ErrorMessages []string          `json:"errorMessages"`
+	}
+	err := json.Unmarshal(body, &raw)
+	if err != nil {
+		return instanceHealthEndpoint{}, err
+	}
+
+	statuses := make([]instanceHealthStatus, len(raw.Statuses))
+	chunk := (len(raw.Statuses) + workers - 1) / workers
+	errs := make([]error, workers)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		start := w * chunk
+		end := start + chunk
+		if start >= len(raw.Statuses) {
+			break
+		}
+		if end > len(raw.Statuses) {
+			end = len(raw.Statuses)
+		}
+
+		wg.Add(1)
+		go func(w, start, end int) {
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				err := json.Unmarshal(raw.Statuses[i], &statuses[i])
+				if err != nil {
+					errs[w] = err
+					return
+				}
+			}
+		}(w, start, end)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return instanceHealthEndpoint{}, err
+		}
+	}
+	return instanceHealthEndpoint{Statuses: statuses, ErrorMessages: raw.ErrorMessages}, nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+const errorPayload = `{"errorMessages":["You do not have the permission to see the specified checks."],"errors":{}}`
+
+func TestDecodeParallel(t *testing.T) {
+	for name, body := range map[string]string{"statuses": mixedPayload, "error object": errorPayload} {
+		var want instanceHealthEndpoint
+		err := json.Unmarshal([]byte(body), &want)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, workers := range []int{2, 3, 8} {
+			got, err := decodeParallel([]byte(body), workers)
+			if err != nil {
+				t.Fatalf("%s with %d workers: %v", name, workers, err)
+			}
+			// compared as printed, the decoders differ in a nil vs. empty statuses slice only
+			if fmt.Sprintf("%+v", got) != fmt.Sprintf("%+v", want) {
+				t.Errorf("%s with %d workers: got %+v, want %+v", name, workers, got, want)
+			}
+		}
+	}
+
+	_, err := decodeParallel([]byte(`{"statuses":[{"id":"one"}]}`), 2)
+	if err == nil {
+		t.Error("got no error for a status that doesn't decode")
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/golang/snappy"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// remoteWriteLabel and remoteWriteSeries mirror the prometheus remote_write protobuf messages (prompb.Label, prompb.TimeSeries).
+type remoteWriteLabel struct {
+	name  string
+	value string
+}
+
+type remoteWriteSeries struct {
+	labels    []remoteWriteLabel
+	value     float64
+	timestamp int64
+}
+
+// remoteWriter periodically gathers the registered metrics and pushes them to a remote_write endpoint.
+type remoteWriter struct {
+	url      string
+	headers  http.Header
+	interval time.Duration
+	gatherer prometheus.Gatherer
+	client   *http.Client
+}
+
+// run sends the metrics every interval. a failed send is retried with an exponential backoff, capped at the interval.
+func (rw *remoteWriter) run() {
+	backoff := time.Duration(0)
+	for {
+		err := rw.send()
+		if err != nil {
+			backoff = nextBackoff(backoff, rw.interval)
+			log.Warn("remote write to ", rw.url, " failed, retry in ", backoff, ": ", err)
+			time.Sleep(backoff)
+			continue
+		}
+
+		backoff = 0
+		time.Sleep(rw.interval)
+	}
+}
+
+// nextBackoff doubles the previous backoff starting at one second, never exceeding max.
+func nextBackoff(previous, max time.Duration) time.Duration {
+	next := previous * 2
+	if next == 0 {
+		next = time.Second
+	}
+	if next > max {
+		next = max
+	}
+	return next
+}
+
+// send gathers the current metrics, encodes them as a snappy compressed remote_write request and POSTs it.
+func (rw *remoteWriter) send() error {
+	log.Debug("gather metrics for remote write")
+	mfs, err := rw.gatherer.Gather()
+	if err != nil {
+		return fmt.Errorf("gather: %w", err)
+	}
+
+	series := familiesToSeries(mfs, time.Now().UnixNano()/int64(time.Millisecond))
+	body := snappy.Encode(nil, encodeWriteRequest(series))
+
+	req, err := http.NewRequest("POST", rw.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range rw.headers {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	log.Debug("remote write ", len(series), " series to: ", rw.url)
+	resp, err := rw.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("server returned %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	return nil
+}
+
+// familiesToSeries flattens gathered metric families into remote_write series, expanding summaries and histograms
+// into their _sum, _count, quantile and _bucket series the same way the text exposition format does.
+func familiesToSeries(mfs []*dto.MetricFamily, now int64) []remoteWriteSeries {
+	var series []remoteWriteSeries
+	for _, mf := range mfs {
+		name := mf.GetName()
+		for _, m := range mf.GetMetric() {
+			ts := now
+			if m.TimestampMs != nil {
+				ts = m.GetTimestampMs()
+			}
+
+			add := func(suffix string, value float64, extra ...remoteWriteLabel) {
+				labels := []remoteWriteLabel{{name: "__name__", value: name + suffix}}
+				for _, l := range m.GetLabel() {
+					labels = append(labels, remoteWriteLabel{name: l.GetName(), value: l.GetValue()})
+				}
+				labels = append(labels, extra...)
+				sort.Slice(labels, func(i, j int) bool { return labels[i].name < labels[j].name })
+				series = append(series, remoteWriteSeries{labels: labels, value: value, timestamp: ts})
+			}
+
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				add("", m.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				add("", m.GetGauge().GetValue())
+			case dto.MetricType_UNTYPED:
+				add("", m.GetUntyped().GetValue())
+			case dto.MetricType_SUMMARY:
+				for _, q := range m.GetSummary().GetQuantile() {
+					add("", q.GetValue(), remoteWriteLabel{name: "quantile", value: formatFloat(q.GetQuantile())})
+				}
+				add("_sum", m.GetSummary().GetSampleSum())
+				add("_count", float64(m.GetSummary().GetSampleCount()))
+			case dto.MetricType_HISTOGRAM:
+				for _, b := range m.GetHistogram().GetBucket() {
+					add("_bucket", float64(b.GetCumulativeCount()), remoteWriteLabel{name: "le", value: formatFloat(b.GetUpperBound())})
+				}
+				add("_bucket", float64(m.GetHistogram().GetSampleCount()), remoteWriteLabel{name: "le", value: "+Inf"})
+				add("_sum", m.GetHistogram().GetSampleSum())
+				add("_count", float64(m.GetHistogram().GetSampleCount()))
+			}
+		}
+	}
+	return series
+}
+
+// formatFloat formats quantile and le label values like the text exposition format.
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// encodeWriteRequest marshals series into a prometheus.WriteRequest protobuf message.
+//
+//	WriteRequest { repeated TimeSeries timeseries = 1; }
+//	TimeSeries   { repeated Label labels = 1; repeated Sample samples = 2; }
+//	Label        { string name = 1; string value = 2; }
+//	Sample       { double value = 1; int64 timestamp = 2; }
+func encodeWriteRequest(series []remoteWriteSeries) []byte {
+	var req []byte
+	for _, s := range series {
+		var ts []byte
+		for _, l := range s.labels {
+			var label []byte
+			label = protowire.AppendTag(label, 1, protowire.BytesType)
+			label = protowire.AppendString(label, l.name)
+			label = protowire.AppendTag(label, 2, protowire.BytesType)
+			label = protowire.AppendString(label, l.value)
+
+			ts = protowire.AppendTag(ts, 1, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, label)
+		}
+
+		var sample []byte
+		sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+		sample = protowire.AppendFixed64(sample, math.Float64bits(s.value))
+		sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+		sample = protowire.AppendVarint(sample, uint64(s.timestamp))
+
+		ts = protowire.AppendTag(ts, 2, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, sample)
+
+		req = protowire.AppendTag(req, 1, protowire.BytesType)
+		req = protowire.AppendBytes(req, ts)
+	}
+	return req
+}
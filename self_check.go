@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+// newSelfCheckFailures creates the counter of self checks that couldn't scrape /metrics or found a metric family missing.
+func newSelfCheckFailures() prometheus.Counter {
+	return prometheus.NewCounter(prometheus.CounterOpts{
+		Name: exporterName + "_self_check_failures_total",
+		Help: "Number of periodic self scrapes of /metrics that failed or were missing an expected metric family",
+	})
+}
+
+// selfCheckFamilies are the metric families every scrape emits, whether or not the endpoint is reachable.
+func selfCheckFamilies() []string {
+	return []string{
+		exporterName + "_scrape_url_up",
+		exporterName + "_exporter_scrape_count",
+	}
+}
+
+// selfChecker periodically scrapes the exporter's own /metrics to catch the collector silently dropping a metric family.
+type selfChecker struct {
+	url      string
+	interval time.Duration
+	client   *http.Client
+	failures prometheus.Counter
+}
+
+// run checks every interval, counting and logging each failure.
+func (c *selfChecker) run() {
+	for {
+		time.Sleep(c.interval)
+
+		err := c.check()
+		if err != nil {
+			log.Warn("self check of ", c.url, " failed: ", err)
+			c.failures.Inc()
+			continue
+		}
+		log.Debug("self check of ", c.url, " passed")
+	}
+}
+
+// check scrapes /metrics once and makes sure every expected metric family is present.
+func (c *selfChecker) check() error {
+	resp, err := c.client.Get(c.url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("returned %d", resp.StatusCode)
+	}
+
+	var parser expfmt.TextParser
+	mfs, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return fmt.Errorf("unable to parse the metrics: %w", err)
+	}
+
+	for _, name := range selfCheckFamilies() {
+		if _, ok := mfs[name]; !ok {
+			return fmt.Errorf("metric family %s is missing", name)
+		}
+	}
+
+	return nil
+}
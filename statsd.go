@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// statsdSender periodically scrapes the endpoint on its own, separate from prometheus, and sends the key gauges to statsd.
+type statsdSender struct {
+	conn     net.Conn
+	prefix   string
+	interval time.Duration
+}
+
+// newStatsdSender is the constructor for the sender, statsd is always udp.
+func newStatsdSender(address, prefix string, interval time.Duration) (*statsdSender, error) {
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &statsdSender{
+		conn:     conn,
+		prefix:   prefix,
+		interval: interval,
+	}, nil
+}
+
+// run sends the gauges every interval.
+func (s *statsdSender) run() {
+	for {
+		s.send()
+		time.Sleep(s.interval)
+	}
+}
+
+// send scrapes the endpoint and writes the up, failing_total and healthy_ratio gauges as a single statsd packet.
+// the checks are filtered like /metrics, healthy_ratio is skipped when the scrape failed or no check is left.
+func (s *statsdSender) send() {
+	m, _, _, err := fetchInstanceHealth(context.Background(), defaultTarget)
+	if err != nil {
+		log.Warn("statsd scrape failed: ", err)
+	}
+
+	lines := []string{s.gauge("up", boolToFloat(err == nil))}
+	if err == nil {
+		m.Statuses = filterStatuses(m.Statuses)
+		failing := 0
+		for _, status := range m.Statuses {
+			if !status.IsHealthy {
+				failing++
+			}
+		}
+
+		lines = append(lines, s.gauge("failing_total", float64(failing)))
+		if len(m.Statuses) > 0 {
+			lines = append(lines, s.gauge("healthy_ratio", float64(len(m.Statuses)-failing)/float64(len(m.Statuses))))
+		}
+	}
+
+	log.Debug("send statsd gauges: ", lines)
+	_, err = s.conn.Write([]byte(strings.Join(lines, "\n")))
+	if err != nil {
+		log.Warn("unable to send to statsd: ", err)
+	}
+}
+
+// gauge formats a statsd gauge line (<prefix>.<name>:<value>|g).
+func (s *statsdSender) gauge(name string, value float64) string {
+	return fmt.Sprintf("%s.%s:%g|g", s.prefix, name, value)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// filteredPayload has a check of each kind the filters drop, see setFilters.
+const filteredPayload = `{"statuses":[
+	{"id":1,"completeKey":"com.atlassian.jira:eol","isHealthy":true,"application":"JIRA"},
+	{"id":2,"completeKey":"com.atlassian.jira:lucene","isHealthy":false,"application":"JIRA"},
+	{"id":3,"completeKey":"com.atlassian.confluence:db","isHealthy":false,"application":"Confluence"},
+	{"id":4,"completeKey":"com.atlassian.jira:db","isHealthy":false,"application":"JIRA"},
+	{"id":4,"completeKey":"com.atlassian.jira:db","isHealthy":false,"application":"JIRA"}
+]}`
+
+// setFilters excludes com.atlassian.jira:lucene and keeps only jira checks until the test ends, which leaves
+// com.atlassian.jira:eol (healthy) and com.atlassian.jira:db (failing) of filteredPayload.
+func setFilters(t *testing.T) {
+	t.Helper()
+	excluded, p := excludedChecks, *product
+	t.Cleanup(func() { excludedChecks, *product = excluded, p })
+
+	excludedChecks = map[string]bool{"com.atlassian.jira:lucene": true}
+	*product = "jira"
+}
+
+func TestStatsdSend(t *testing.T) {
+	defer func(target scrapeTarget) { defaultTarget = target }(defaultTarget)
+	setFilters(t)
+
+	u := newTestUpstream(t, respond(http.StatusOK, filteredPayload))
+	defaultTarget = u.target()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	s, err := newStatsdSender(conn.LocalAddr().String(), "aihe", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.send()
+
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "aihe.up:1|g\naihe.failing_total:1|g\naihe.healthy_ratio:0.5|g"
+	if got := string(buf[:n]); got != want {
+		t.Errorf("got the statsd packet %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"gopkg.in/yaml.v2"
+)
+
+// scrapeTarget is an application instance the exporter scrapes.
+type scrapeTarget struct {
+	fqdn     string
+	protocol string
+	token    string
+
+	// url is the troubleshooting endpoint of the instance.
+	url string
+}
+
+// newScrapeTarget is the constructor for a target, token is in the app.token format.
+func newScrapeTarget(fqdn, protocol, token string) scrapeTarget {
+	return scrapeTarget{
+		fqdn:     fqdn,
+		protocol: protocol,
+		token:    token,
+		url:      protocol + "://" + fqdn + "/rest/troubleshooting/1.0/check/",
+	}
+}
+
+// targetEntry is an entry of the targets.file list. protocol and token default to app.protocol and app.token.
+type targetEntry struct {
+	Fqdn     string `yaml:"fqdn"`
+	Protocol string `yaml:"protocol"`
+	Token    string `yaml:"token"`
+}
+
+// loadTargetsFile reads the yaml (or json) list of targets at path. every fqdn has to be set and unique.
+func loadTargetsFile(path, defaultProtocol, defaultToken string) ([]scrapeTarget, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []targetEntry
+	err = yaml.UnmarshalStrict(b, &entries)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%s has no targets", path)
+	}
+
+	targets := make([]scrapeTarget, 0, len(entries))
+	seen := map[string]bool{}
+	for i, e := range entries {
+		if e.Fqdn == "" {
+			return nil, fmt.Errorf("target %d in %s has no fqdn", i+1, path)
+		}
+		if seen[e.Fqdn] {
+			return nil, fmt.Errorf("target %s is listed twice in %s", e.Fqdn, path)
+		}
+		seen[e.Fqdn] = true
+
+		if e.Protocol == "" {
+			e.Protocol = defaultProtocol
+		}
+		if e.Protocol != "http" && e.Protocol != "https" {
+			return nil, fmt.Errorf("target %s has protocol %q, expected http or https", e.Fqdn, e.Protocol)
+		}
+
+		if e.Token == "" {
+			e.Token = defaultToken
+		}
+		if e.Token == "" && *authScheme != "awssigv4" {
+			return nil, fmt.Errorf("target %s has no token and app.token is not set", e.Fqdn)
+		}
+
+		targets = append(targets, newScrapeTarget(e.Fqdn, e.Protocol, e.Token))
+	}
+	return targets, nil
+}
+
+// targetsCollector scrapes every targets.file instance on each collection, at most workers at a time.
+// every target's collector emits the same descriptors, so it is registered unchecked (Describe sends nothing),
+// and metrics without a fqdn label (ie. the connection counters) get the target's fqdn added to stay unique.
+type targetsCollector struct {
+	collectors []*instanceHealthCollector
+	workers    int
+}
+
+// Describe sends nothing, which registers the collector as unchecked. checkDescs validates the descriptors instead.
+func (t *targetsCollector) Describe(chan<- *prometheus.Desc) {}
+
+// checkDescs registers the first target's collector with a throwaway registry, so an invalid descriptor
+// (ie. a label.rename or http.header-metrics name colliding with fqdn) fails at startup instead of on the first scrape.
+// every target's collector has the same descriptors.
+func (t *targetsCollector) checkDescs() error {
+	return prometheus.NewRegistry().Register(t.collectors[0])
+}
+
+// Collect runs the collectors in a bounded pool of workers and forwards their metrics labeled with the target.
+func (t *targetsCollector) Collect(ch chan<- prometheus.Metric) {
+	fqdnLabel := renameLabels([]string{"fqdn"})[0]
+
+	jobs := make(chan *instanceHealthCollector)
+	var wg sync.WaitGroup
+	for i := 0; i < t.workers && i < len(t.collectors); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range jobs {
+				log.Debug("collect target: ", c.target.fqdn)
+				metrics := make(chan prometheus.Metric)
+				done := make(chan struct{})
+				go func(fqdn string) {
+					for m := range metrics {
+						ch <- targetMetric{Metric: m, label: fqdnLabel, fqdn: fqdn}
+					}
+					close(done)
+				}(c.target.fqdn)
+				c.Collect(metrics)
+				close(metrics)
+				<-done
+			}
+		}()
+	}
+
+	for _, c := range t.collectors {
+		jobs <- c
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// scrapedOnce reports whether any target has been scraped successfully.
+func (t *targetsCollector) scrapedOnce() bool {
+	for _, c := range t.collectors {
+		if c.scrapedOnce() {
+			return true
+		}
+	}
+	return false
+}
+
+// ready reports whether any target had a successful scrape within maxStaleness, one instance being down
+// shouldn't take the exporter of every other one out of service.
+func (t *targetsCollector) ready(maxStaleness time.Duration) bool {
+	for _, c := range t.collectors {
+		if c.ready(maxStaleness) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeSummary writes the table of each target's latest checks to w, under its fqdn.
+func (t *targetsCollector) writeSummary(w io.Writer) {
+	for _, c := range t.collectors {
+		fmt.Fprintln(w, c.target.fqdn)
+		c.writeSummary(w)
+	}
+}
+
+// targetMetric adds the fqdn label to a metric of a target's collector when it doesn't carry one.
+type targetMetric struct {
+	prometheus.Metric
+	label string
+	fqdn  string
+}
+
+// Write implements prometheus.Metric, appending the fqdn label pair and keeping the pairs sorted by name.
+func (m targetMetric) Write(out *dto.Metric) error {
+	err := m.Metric.Write(out)
+	if err != nil {
+		return err
+	}
+
+	for _, lp := range out.Label {
+		if lp.GetName() == m.label {
+			return nil
+		}
+	}
+
+	name, value := m.label, m.fqdn
+	out.Label = append(out.Label, &dto.LabelPair{Name: &name, Value: &value})
+	sort.Slice(out.Label, func(i, j int) bool {
+		return out.Label[i].GetName() < out.Label[j].GetName()
+	})
+	return nil
+}
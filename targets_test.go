@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestTargetsCollector(t *testing.T) {
+	healthy := newTestUpstream(t, respond(http.StatusOK, healthyPayload))
+	failing := newTestUpstream(t, respond(http.StatusInternalServerError, "oops"))
+
+	tc := &targetsCollector{
+		collectors: []*instanceHealthCollector{newTestCollector(healthy), newTestCollector(failing)},
+		workers:    2,
+	}
+
+	expected := fmt.Sprintf(`
+# HELP atlassian_instance_health metric used to monitor the Atlassian Troubleshooting and Support Tools Plugin endpoint (https://<url>/rest/troubleshooting/1.0/check/)
+# TYPE atlassian_instance_health gauge
+atlassian_instance_health{application="JIRA",completekey="com.atlassian.jira:eol",description="Checks the version is supported",documentation="https://confluence.atlassian.com/x/eol",failurereason="",fqdn="%[1]s",healthy="true",id="1",ishealthy="true",name="End of Life",name_slug="end_of_life",severity="undefined",tag="Supported Platforms",time="1600000000000"} 1
+# HELP atlassian_instance_health_scrape_url_up metric used to check if the rest endpoint is accessible (https://<url>/rest/troubleshooting/1.0/check/)
+# TYPE atlassian_instance_health_scrape_url_up gauge
+atlassian_instance_health_scrape_url_up{error="",fqdn="%[1]s",httpcode="200"} 1
+atlassian_instance_health_scrape_url_up{error="",fqdn="%[2]s",httpcode="500"} 0
+# HELP atlassian_instance_health_scrape_errors_total Number of scrapes of the application that failed, didn't unmarshal or returned a non-2xx status
+# TYPE atlassian_instance_health_scrape_errors_total counter
+atlassian_instance_health_scrape_errors_total{fqdn="%[1]s"} 0
+atlassian_instance_health_scrape_errors_total{fqdn="%[2]s"} 1
+`, healthy.host(), failing.host())
+
+	err := testutil.CollectAndCompare(tc, strings.NewReader(expected),
+		"atlassian_instance_health", "atlassian_instance_health_scrape_url_up", "atlassian_instance_health_scrape_errors_total")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tc.scrapedOnce() {
+		t.Error("got scrapedOnce false with a healthy target")
+	}
+}
+
+func TestLoadTargetsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "targets.yml")
+	err := os.WriteFile(path, []byte(`
+- fqdn: jira.domain.com
+- fqdn: confluence.domain.com
+  protocol: http
+  token: Y29uZmx1ZW5jZQ==
+`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	targets, err := loadTargetsFile(path, "https", "dGVzdDp0ZXN0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []scrapeTarget{
+		newScrapeTarget("jira.domain.com", "https", "dGVzdDp0ZXN0"),
+		newScrapeTarget("confluence.domain.com", "http", "Y29uZmx1ZW5jZQ=="),
+	}
+	if fmt.Sprint(targets) != fmt.Sprint(want) {
+		t.Errorf("got targets %+v, want %+v", targets, want)
+	}
+}
+
+func TestTargetsFileConflicts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "targets.yml")
+	err := os.WriteFile(path, []byte("- fqdn: jira.domain.com\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, arg := range []string{"-app.fqdn=jira.domain.com", "-metrics.shard-count=4"} {
+		name := strings.SplitN(strings.TrimPrefix(arg, "-"), "=", 2)[0]
+		out, err := runMain(t, "-app.token=dGVzdDp0ZXN0", "-targets.file="+path, arg)
+		if err != nil {
+			t.Errorf("%s: main exited with %v, want usage", arg, err)
+		}
+		if want := name + " can't be used with targets.file."; !strings.Contains(out, want) {
+			t.Errorf("%s: main printed %q, want %q", arg, out, want)
+		}
+	}
+}
+
+// TestTargetsCollectorCheckDescs checks a label colliding with fqdn is caught although targetsCollector registers unchecked.
+func TestTargetsCollectorCheckDescs(t *testing.T) {
+	defer func(renames map[string]string) { labelRenames = renames }(labelRenames)
+	defer func(names []string) { headerMetricNames = names }(headerMetricNames)
+
+	u := newTestUpstream(t, respond(http.StatusOK, healthyPayload))
+
+	for _, tt := range []struct {
+		name    string
+		renames map[string]string
+		headers []string
+		wantErr bool
+	}{
+		{name: "default", renames: map[string]string{}},
+		{name: "fqdn renamed away", renames: map[string]string{"fqdn": "host", "id": "fqdn"}},
+		{name: "rename onto fqdn", renames: map[string]string{"id": "fqdn"}, wantErr: true},
+		{name: "header named fqdn", renames: map[string]string{}, headers: []string{"fqdn"}, wantErr: true},
+	} {
+		labelRenames, headerMetricNames = tt.renames, tt.headers
+		tc := &targetsCollector{collectors: []*instanceHealthCollector{newTestCollector(u)}, workers: 1}
+
+		err := tc.checkDescs()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: got error %v, want error %t", tt.name, err, tt.wantErr)
+		}
+		if err := prometheus.NewRegistry().Register(tc); err != nil {
+			t.Errorf("%s: got register error %v, want the unchecked registration to pass", tt.name, err)
+		}
+	}
+}
+
+func TestTargetsFileLabelCollision(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "targets.yml")
+	err := os.WriteFile(path, []byte("- fqdn: jira.domain.com\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		arg  string
+		want string
+	}{
+		{arg: "-label.rename=id=fqdn", want: "invalid label.rename"},
+		{arg: "-http.header-metrics=fqdn", want: "invalid metric descriptors"},
+	} {
+		out, err := runMain(t, "-app.token=dGVzdDp0ZXN0", "-targets.file="+path, tt.arg)
+		if err == nil {
+			t.Errorf("%s: main exited with 0, want a startup failure", tt.arg)
+		}
+		if !strings.Contains(out, tt.want) {
+			t.Errorf("%s: main printed %q, want %q", tt.arg, out, tt.want)
+		}
+	}
+}
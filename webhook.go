@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"text/template"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// webhookMaxBackoff caps the wait between webhook delivery attempts.
+const webhookMaxBackoff = 30 * time.Second
+
+// webhookPayload is the data sent for a check that became unhealthy, and passed to webhook.template.
+type webhookPayload struct {
+	Fqdn  string               `json:"fqdn"`
+	Check instanceHealthStatus `json:"check"`
+}
+
+// webhookNotifier POSTs a payload to a webhook whenever a check goes from healthy to unhealthy.
+type webhookNotifier struct {
+	url      string
+	template *template.Template
+	retries  int
+	client   *http.Client
+}
+
+// newWebhookNotifier is the constructor for the notifier. when tmpl is empty the payload is the json encoded webhookPayload.
+func newWebhookNotifier(url, tmpl string, retries int) (*webhookNotifier, error) {
+	n := &webhookNotifier{
+		url:     url,
+		retries: retries,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+
+	if tmpl != "" {
+		t, err := template.New("webhook").Parse(tmpl)
+		if err != nil {
+			return nil, err
+		}
+		n.template = t
+	}
+
+	return n, nil
+}
+
+// notify sends the payload for check of the instance at fqdn in the background, retrying failed deliveries with a backoff.
+func (n *webhookNotifier) notify(fqdn string, check instanceHealthStatus) {
+	payload := webhookPayload{Fqdn: fqdn, Check: check}
+
+	body, err := n.render(payload)
+	if err != nil {
+		log.Error("unable to render the webhook payload for ", check.CompleteKey, ": ", err)
+		return
+	}
+
+	go func() {
+		backoff := time.Duration(0)
+		for attempt := 0; ; attempt++ {
+			err := n.send(body)
+			if err == nil {
+				log.Debug("webhook sent for: ", check.CompleteKey)
+				return
+			}
+			if attempt >= n.retries {
+				log.Error("webhook for ", check.CompleteKey, " failed after ", attempt+1, " attempts: ", err)
+				return
+			}
+
+			backoff = nextBackoff(backoff, webhookMaxBackoff)
+			log.Warn("webhook for ", check.CompleteKey, " failed, retry in ", backoff, ": ", err)
+			time.Sleep(backoff)
+		}
+	}()
+}
+
+// render executes webhook.template with the payload, or json encodes it when no template is set.
+func (n *webhookNotifier) render(payload webhookPayload) ([]byte, error) {
+	if n.template == nil {
+		return json.Marshal(payload)
+	}
+
+	var buf bytes.Buffer
+	err := n.template.Execute(&buf, payload)
+	return buf.Bytes(), err
+}
+
+// send POSTs the body once, any non-2xx response is an error.
+func (n *webhookNotifier) send(body []byte) error {
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook returned %d", resp.StatusCode)
+	}
+	return nil
+}
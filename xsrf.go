@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// xsrf is set in main when app.xsrf-path is, nil otherwise.
+var xsrf *xsrfTokenSource
+
+// xsrfTokenSource fetches the xsrf token required by instances with csrf protection on the rest endpoints,
+// and keeps it for the following scrapes until the endpoint answers 403.
+type xsrfTokenSource struct {
+	url    string
+	cookie string
+	header string
+
+	mu    sync.Mutex
+	token string
+}
+
+// apply adds the token to req, as the cookie it was issued in and as the header. refresh fetches a new token first.
+func (x *xsrfTokenSource) apply(req *http.Request, refresh bool) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.token == "" || refresh {
+		token, err := x.fetch(req.Context())
+		if err != nil {
+			return err
+		}
+		x.token = token
+	}
+
+	req.AddCookie(&http.Cookie{Name: x.cookie, Value: x.token})
+	req.Header.Set(x.header, x.token)
+	return nil
+}
+
+// fetch requests the token url with the scrape's authorization and reads the token from the cookie, or else from a response header of the same name.
+func (x *xsrfTokenSource) fetch(ctx context.Context) (string, error) {
+	log.Debug("fetch the xsrf token from: ", x.url)
+	req, err := http.NewRequestWithContext(ctx, "GET", x.url, nil)
+	if err != nil {
+		return "", fmt.Errorf("unable to create the xsrf token request: %w", err)
+	}
+	req.Header.Set("User-Agent", *userAgent)
+	err = authorize(req, defaultTarget.token)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("the xsrf token request returned an error: %w", err)
+	}
+	resp.Body.Close()
+
+	for _, c := range resp.Cookies() {
+		if c.Name == x.cookie && c.Value != "" {
+			return c.Value, nil
+		}
+	}
+	if token := resp.Header.Get(x.cookie); token != "" {
+		return token, nil
+	}
+	return "", fmt.Errorf("no %s cookie in the xsrf token response (%d)", x.cookie, resp.StatusCode)
+}